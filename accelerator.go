@@ -0,0 +1,143 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// modifierSpec pairs a Modifier with its canonical accelerator name and
+// any additional spellings Parse accepts for it.
+type modifierSpec struct {
+	mod     Modifier
+	name    string
+	aliases []string
+}
+
+// acceleratorModifiers lists, in the canonical rendering order, every
+// modifier Parse and String understand.
+var acceleratorModifiers = []modifierSpec{
+	{ModCtrl, "Ctrl", []string{"Control"}},
+	{ModOption, "Alt", []string{"Option"}},
+	{ModShift, "Shift", nil},
+	{ModCmd, "Meta", []string{"Super", "Win", "Cmd", "Command"}},
+}
+
+// Parse parses a human readable accelerator string such as
+// "CmdOrCtrl+Alt+F5", "Ctrl+Shift+S", or "Meta+Space" into a Hotkey.
+// CmdOrCtrl is a platform-neutral alias that resolves to ModCmd on
+// macOS and ModCtrl elsewhere, so the same accelerator string can be
+// used across platforms for the conventional "primary" shortcut
+// modifier.
+func Parse(spec string) (*Hotkey, error) {
+	mods, key, err := parseAccelerator(spec)
+	if err != nil {
+		return nil, err
+	}
+	return New(mods, key), nil
+}
+
+// parseAccelerator does the parsing work behind Parse, without
+// allocating a Hotkey. It is also used by Hotkey.UnmarshalText, which
+// must populate an existing *Hotkey rather than build a new one.
+func parseAccelerator(spec string) ([]Modifier, Key, error) {
+	parts := strings.Split(spec, "+")
+	if len(parts) < 2 {
+		return nil, 0, fmt.Errorf("hotkey: invalid accelerator %q", spec)
+	}
+
+	mods := make([]Modifier, 0, len(parts)-1)
+	for _, p := range parts[:len(parts)-1] {
+		p = strings.TrimSpace(p)
+		if strings.EqualFold(p, "CmdOrCtrl") || strings.EqualFold(p, "CommandOrControl") {
+			mods = append(mods, cmdOrCtrl)
+			continue
+		}
+		mod, ok := lookupModifier(p)
+		if !ok {
+			return nil, 0, fmt.Errorf("hotkey: unknown modifier %q in %q", p, spec)
+		}
+		mods = append(mods, mod)
+	}
+
+	keyName := strings.TrimSpace(parts[len(parts)-1])
+	key, ok := lookupKey(keyName)
+	if !ok {
+		return nil, 0, fmt.Errorf("hotkey: unknown key %q in %q", keyName, spec)
+	}
+
+	return mods, key, nil
+}
+
+func lookupModifier(name string) (Modifier, bool) {
+	for _, m := range acceleratorModifiers {
+		if strings.EqualFold(m.name, name) {
+			return m.mod, true
+		}
+		for _, alias := range m.aliases {
+			if strings.EqualFold(alias, name) {
+				return m.mod, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// lookupKey resolves an accelerator key name against KeyMap, accepting
+// both the bare spelling ("S", "F5") and the KeyMap spelling ("KeyS",
+// "KeyF5"), case-insensitively to match lookupModifier.
+func lookupKey(name string) (Key, bool) {
+	for keyMapName, key := range KeyMap {
+		if strings.EqualFold(keyMapName, name) || strings.EqualFold(keyMapName, "Key"+name) {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+// String returns the canonical accelerator form of the hotkey, such as
+// "Ctrl+Shift+S". Unlike the key+mods order hotkeys were historically
+// printed in, this form is accepted back by Parse.
+func (hk *Hotkey) String() string {
+	mods := make([]Modifier, len(hk.mods))
+	copy(mods, hk.mods)
+	sort.Slice(mods, func(i, j int) bool {
+		return acceleratorRank(mods[i]) < acceleratorRank(mods[j])
+	})
+
+	var b strings.Builder
+	for _, mod := range mods {
+		b.WriteString(modifierName(mod))
+		b.WriteByte('+')
+	}
+	b.WriteString(keyName(hk.key))
+	return b.String()
+}
+
+func acceleratorRank(mod Modifier) int {
+	for i, m := range acceleratorModifiers {
+		if m.mod == mod {
+			return i
+		}
+	}
+	return len(acceleratorModifiers)
+}
+
+func modifierName(mod Modifier) string {
+	for _, m := range acceleratorModifiers {
+		if m.mod == mod {
+			return m.name
+		}
+	}
+	return fmt.Sprintf("%v", mod)
+}
+
+func keyName(key Key) string {
+	return strings.TrimPrefix(fmt.Sprintf("%v", key), "Key")
+}
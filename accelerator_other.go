@@ -0,0 +1,13 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build !darwin
+
+package hotkey
+
+// cmdOrCtrl is the modifier the platform-neutral "CmdOrCtrl" accelerator
+// alias resolves to: Ctrl on every platform other than macOS.
+const cmdOrCtrl = ModCtrl
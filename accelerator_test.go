@@ -0,0 +1,74 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey_test
+
+import (
+	"testing"
+
+	"golang.design/x/hotkey"
+)
+
+func TestParseAndStringRoundTrip(t *testing.T) {
+	// Each of these is already in canonical (Ctrl, Alt, Shift, Meta)
+	// modifier order, so Parse followed by String must echo it back
+	// unchanged.
+	specs := []string{
+		"Ctrl+Shift+S",
+		"Alt+F5",
+		"Meta+Space",
+		"Ctrl+Alt+Shift+A",
+	}
+
+	for _, spec := range specs {
+		hk, err := hotkey.Parse(spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", spec, err)
+		}
+		if got := hk.String(); got != spec {
+			t.Errorf("Parse(%q).String() = %q, want %q", spec, got, spec)
+		}
+	}
+}
+
+func TestParseNormalizesModifierOrder(t *testing.T) {
+	hk, err := hotkey.Parse("Shift+Alt+Ctrl+A")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got, want := hk.String(), "Ctrl+Alt+Shift+A"; got != want {
+		t.Errorf("Parse(\"Shift+Alt+Ctrl+A\").String() = %q, want %q", got, want)
+	}
+}
+
+func TestParseCaseInsensitive(t *testing.T) {
+	hk, err := hotkey.Parse("ctrl+shift+s")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got, want := hk.String(), "Ctrl+Shift+S"; got != want {
+		t.Errorf("Parse(\"ctrl+shift+s\").String() = %q, want %q", got, want)
+	}
+}
+
+func TestParseCmdOrCtrl(t *testing.T) {
+	hk, err := hotkey.Parse("CmdOrCtrl+P")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got := hk.String(); got != "Ctrl+P" && got != "Meta+P" {
+		t.Errorf("Parse(\"CmdOrCtrl+P\").String() = %q, want \"Ctrl+P\" or \"Meta+P\" depending on platform", got)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := []string{"", "S", "Ctrl+Unknown", "Bogus+S"}
+	for _, spec := range cases {
+		if _, err := hotkey.Parse(spec); err == nil {
+			t.Errorf("Parse(%q): expected an error, got nil", spec)
+		}
+	}
+}
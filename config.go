@@ -0,0 +1,108 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MarshalText implements encoding.TextMarshaler, rendering the modifier
+// in its canonical accelerator spelling, e.g. "Ctrl".
+func (m Modifier) MarshalText() ([]byte, error) {
+	return []byte(modifierName(m)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Modifier) UnmarshalText(text []byte) error {
+	mod, ok := lookupModifier(string(text))
+	if !ok {
+		return fmt.Errorf("hotkey: unknown modifier %q", text)
+	}
+	*m = mod
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, rendering the key in
+// its canonical accelerator spelling, e.g. "S" or "F5".
+func (k Key) MarshalText() ([]byte, error) {
+	return []byte(keyName(k)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It reports an
+// error if the key does not exist in the current platform's KeyMap.
+func (k *Key) UnmarshalText(text []byte) error {
+	key, ok := lookupKey(string(text))
+	if !ok {
+		return fmt.Errorf("hotkey: unknown key %q", text)
+	}
+	*k = key
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, rendering the hotkey
+// as its canonical accelerator string, e.g. "Ctrl+Shift+S".
+//
+// Because Go's encoding/json falls back to TextMarshaler/TextUnmarshaler
+// when a type has no MarshalJSON/UnmarshalJSON of its own, this is
+// enough to make Hotkey, Key and Modifier marshal as plain accelerator
+// strings in JSON; TOML encoders such as BurntSushi/toml use the same
+// interfaces, so Config round-trips through either format unchanged.
+func (hk *Hotkey) MarshalText() ([]byte, error) {
+	return []byte(hk.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It reports an
+// error if the accelerator string is malformed or names a key that
+// does not exist in the current platform's KeyMap.
+//
+// It populates hk in place rather than going through Parse: Parse
+// returns a *Hotkey built by New, which owns its own event channels and
+// a finalizer that closes them. Copying that struct over hk (*hk =
+// *parsed) would leave those channels shared between hk and the
+// now-unreachable parsed value, so parsed's finalizer would close them
+// out from under hk the next time it runs.
+func (hk *Hotkey) UnmarshalText(text []byte) error {
+	mods, key, err := parseAccelerator(string(text))
+	if err != nil {
+		return err
+	}
+	hk.mods = mods
+	hk.key = key
+	if hk.keydownIn == nil {
+		hk.init()
+	}
+	return nil
+}
+
+// Config is a persistable set of hotkey bindings, keyed by an
+// application-defined action name. Encoded as JSON or TOML, each
+// binding round-trips through its canonical accelerator string, so a
+// Config file stays human-editable, e.g.:
+//
+//	{
+//	  "save":     "Ctrl+S",
+//	  "quick-open": "CmdOrCtrl+P"
+//	}
+type Config map[string]*Hotkey
+
+// ReadConfig decodes a Config previously written by WriteConfig.
+func ReadConfig(r io.Reader) (Config, error) {
+	cfg := Config{}
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// WriteConfig encodes cfg so it can later be restored with ReadConfig.
+func WriteConfig(w io.Writer, cfg Config) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cfg)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"golang.design/x/hotkey"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	cfg := hotkey.Config{
+		"save":       hotkey.New([]hotkey.Modifier{hotkey.ModCtrl}, hotkey.KeyS),
+		"quick-open": hotkey.New([]hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}, hotkey.KeyP),
+	}
+
+	var buf bytes.Buffer
+	if err := hotkey.WriteConfig(&buf, cfg); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+
+	got, err := hotkey.ReadConfig(&buf)
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+
+	for action, want := range cfg {
+		hk, ok := got[action]
+		if !ok {
+			t.Fatalf("ReadConfig: missing action %q", action)
+		}
+		if hk.String() != want.String() {
+			t.Errorf("action %q: got %q, want %q", action, hk.String(), want.String())
+		}
+
+		// Regression test: UnmarshalText must leave hk fully initialized
+		// (event channels set up via init, not aliased into a
+		// finalizer-owning Hotkey that Parse then discards).
+		if hk.Keydown() == nil || hk.Keyup() == nil {
+			t.Errorf("action %q: UnmarshalText left the event channels uninitialized", action)
+		}
+	}
+}
+
+func TestConfigUnmarshalUnknownKey(t *testing.T) {
+	var cfg hotkey.Config
+	err := json.Unmarshal([]byte(`{"bad":"Ctrl+NotAKey"}`), &cfg)
+	if err == nil {
+		t.Fatal("Unmarshal: expected an error for an unknown key, got nil")
+	}
+}
+
+func TestModifierTextRoundTrip(t *testing.T) {
+	b, err := hotkey.ModCtrl.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText: %v", err)
+	}
+	var m hotkey.Modifier
+	if err := m.UnmarshalText(b); err != nil {
+		t.Fatalf("UnmarshalText(%q): %v", b, err)
+	}
+	if m != hotkey.ModCtrl {
+		t.Errorf("UnmarshalText(%q) = %v, want ModCtrl", b, m)
+	}
+}
+
+func TestKeyTextRoundTrip(t *testing.T) {
+	b, err := hotkey.KeyS.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText: %v", err)
+	}
+	var k hotkey.Key
+	if err := k.UnmarshalText(b); err != nil {
+		t.Fatalf("UnmarshalText(%q): %v", b, err)
+	}
+	if k != hotkey.KeyS {
+		t.Errorf("UnmarshalText(%q) = %v, want KeyS", b, k)
+	}
+}
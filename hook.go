@@ -0,0 +1,79 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey
+
+import "time"
+
+// KeyEvent describes a single key transition observed by a Hook.
+type KeyEvent struct {
+	Key  Key
+	Mods Modifier
+	Down bool
+	// Time is the monotonic time elapsed between the Hook starting to
+	// listen (on Windows, the first Hook started in the process) and
+	// the moment the event was observed.
+	Time time.Duration
+}
+
+// Hook installs a system-wide, low-level keyboard listener and reports
+// every key transition it observes, regardless of whether a Hotkey has
+// been registered for it. Unlike Register, a Hook does not consume the
+// keystrokes it reports unless its filter asks the platform to suppress
+// them (and only where the platform allows it), which makes it suitable
+// for autoclickers, macro recorders, and push-to-talk tools that need to
+// see keys the rest of the system still receives.
+//
+// Register is intentionally not rebuilt on top of Hook: Register grabs
+// a key exclusively at the OS level (XGrabKey, RegisterHotKey, ...) so
+// that only this process sees it, whereas Hook observes a copy of every
+// keystroke without taking it away from whichever window has focus.
+// Rebuilding one in terms of the other would either make registered
+// hotkeys stop being exclusive or make the Hook unable to see keys that
+// are already grabbed; the two coexist as separate, complementary APIs
+// instead, sharing only their KeyEvent/Event plumbing.
+type Hook struct {
+	platformHook
+
+	filter func(KeyEvent) bool
+
+	eventsIn  chan<- KeyEvent
+	eventsOut <-chan KeyEvent
+}
+
+// NewHook creates a Hook that reports every key transition and asks the
+// platform to suppress those for which filter returns true. A nil filter
+// never asks the platform to suppress a key transition.
+func NewHook(filter func(KeyEvent) bool) *Hook {
+	if filter == nil {
+		filter = func(KeyEvent) bool { return false }
+	}
+	in, out := newChan[KeyEvent]()
+	return &Hook{
+		filter:    filter,
+		eventsIn:  in,
+		eventsOut: out,
+	}
+}
+
+// Start installs the low-level keyboard listener.
+func (h *Hook) Start() error { return h.start() }
+
+// Stop removes the low-level keyboard listener.
+func (h *Hook) Stop() error { return h.stop() }
+
+// Keys returns the channel on which observed key transitions are
+// delivered.
+func (h *Hook) Keys() <-chan KeyEvent { return h.eventsOut }
+
+// dispatch is called by the platform-specific implementation for every
+// observed key transition. It reports whether the event should be
+// suppressed from the rest of the system, per the Hook's filter.
+func (h *Hook) dispatch(e KeyEvent) (suppress bool) {
+	suppress = h.filter(e)
+	h.eventsIn <- e
+	return suppress
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build darwin
+
+package hotkey
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices
+#include <ApplicationServices/ApplicationServices.h>
+
+extern bool hookKeyEvent(uintptr_t handle, CGEventType type, CGKeyCode keycode, CGEventFlags flags);
+
+static CGEventRef hookTapCallback(CGEventTapProxy proxy, CGEventType type, CGEventRef event, void *refcon) {
+	if (type != kCGEventKeyDown && type != kCGEventKeyUp && type != kCGEventFlagsChanged) {
+		return event;
+	}
+	CGKeyCode keycode = (CGKeyCode)CGEventGetIntegerValueField(event, kCGKeyboardEventKeycode);
+	CGEventFlags flags = CGEventGetFlags(event);
+	bool suppress = hookKeyEvent((uintptr_t)refcon, type, keycode, flags);
+	if (suppress) {
+		return NULL;
+	}
+	return event;
+}
+
+// Unlike Watcher, which only ever listens, Hook installs a tap that can
+// suppress events: its filter decides per key transition whether the
+// rest of the system should see it.
+static CFMachPortRef hookCreateTap(uintptr_t handle) {
+	CGEventMask mask = CGEventMaskBit(kCGEventKeyDown) |
+		CGEventMaskBit(kCGEventKeyUp) |
+		CGEventMaskBit(kCGEventFlagsChanged);
+	return CGEventTapCreate(kCGHIDEventTap, kCGHeadInsertEventTap,
+		kCGEventTapOptionDefault, mask, hookTapCallback, (void *)handle);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// darwinKeycodeToKey maps the ANSI virtual keycodes CGEventTap reports
+// to this package's Key constants, covering every key in KeyMap.
+var darwinKeycodeToKey = map[C.CGKeyCode]Key{
+	0x00: KeyA, 0x01: KeyS, 0x02: KeyD, 0x03: KeyF, 0x05: KeyG, 0x04: KeyH,
+	0x06: KeyZ, 0x07: KeyX, 0x08: KeyC, 0x09: KeyV, 0x0B: KeyB, 0x0C: KeyQ,
+	0x0D: KeyW, 0x0E: KeyE, 0x0F: KeyR, 0x10: KeyY, 0x11: KeyT, 0x1F: KeyO,
+	0x20: KeyU, 0x22: KeyI, 0x23: KeyP, 0x25: KeyL, 0x26: KeyJ, 0x28: KeyK,
+	0x2D: KeyN, 0x2E: KeyM,
+
+	0x12: Key1, 0x13: Key2, 0x14: Key3, 0x15: Key4, 0x17: Key5,
+	0x16: Key6, 0x1A: Key7, 0x1C: Key8, 0x19: Key9, 0x1D: Key0,
+
+	0x24: KeyReturn, 0x35: KeyEscape, 0x33: KeyDelete, 0x30: KeyTab, 0x31: KeySpace,
+
+	0x7B: KeyLeft, 0x7C: KeyRight, 0x7D: KeyDown, 0x7E: KeyUp,
+
+	0x7A: KeyF1, 0x78: KeyF2, 0x63: KeyF3, 0x76: KeyF4, 0x60: KeyF5,
+	0x61: KeyF6, 0x62: KeyF7, 0x64: KeyF8, 0x65: KeyF9, 0x6D: KeyF10,
+	0x67: KeyF11, 0x6F: KeyF12, 0x69: KeyF13, 0x6B: KeyF14, 0x71: KeyF15,
+	0x6A: KeyF16, 0x40: KeyF17, 0x4F: KeyF18, 0x50: KeyF19, 0x5A: KeyF20,
+}
+
+type platformHook struct {
+	tap       C.CFMachPortRef
+	source    C.CFRunLoopSourceRef
+	startTime time.Time
+}
+
+var (
+	hookHandlesMu sync.Mutex
+	hookHandles   = map[C.uintptr_t]*Hook{}
+	hookHandleSeq C.uintptr_t
+)
+
+// start installs a CGEventTap over key down/up/modifier-change events.
+// As with Register on macOS, this requires an active CFRunLoop on the
+// main thread; see golang.design/x/hotkey/mainthread.
+func (h *Hook) start() error {
+	hookHandlesMu.Lock()
+	hookHandleSeq++
+	handle := hookHandleSeq
+	hookHandles[handle] = h
+	hookHandlesMu.Unlock()
+
+	tap := C.hookCreateTap(handle)
+	if tap == 0 {
+		hookHandlesMu.Lock()
+		delete(hookHandles, handle)
+		hookHandlesMu.Unlock()
+		return errors.New("hotkey: failed to create key event tap (missing accessibility permission?)")
+	}
+	source := C.CFMachPortCreateRunLoopSource(0, tap, 0)
+	C.CFRunLoopAddSource(C.CFRunLoopGetMain(), source, C.kCFRunLoopCommonModes)
+	C.CGEventTapEnable(tap, C.true)
+
+	h.tap = tap
+	h.source = source
+	h.startTime = time.Now()
+	return nil
+}
+
+func (h *Hook) stop() error {
+	if h.tap == 0 {
+		return nil
+	}
+	C.CGEventTapEnable(h.tap, C.false)
+	C.CFRunLoopRemoveSource(C.CFRunLoopGetMain(), h.source, C.kCFRunLoopCommonModes)
+	C.CFRelease(C.CFTypeRef(h.source))
+	C.CFRelease(C.CFTypeRef(h.tap))
+	h.tap = 0
+	return nil
+}
+
+//export hookKeyEvent
+func hookKeyEvent(handle C.uintptr_t, typ C.CGEventType, keycode C.CGKeyCode, flags C.CGEventFlags) C.bool {
+	hookHandlesMu.Lock()
+	h := hookHandles[handle]
+	hookHandlesMu.Unlock()
+	if h == nil {
+		return C.bool(false)
+	}
+
+	var mods Modifier
+	for mod, bit := range darwinModifierFlags {
+		if flags&bit != 0 {
+			mods |= mod
+		}
+	}
+
+	if typ == C.kCGEventFlagsChanged {
+		// FlagsChanged reports a modifier transition, not a key with a
+		// down/up pair; there is nothing meaningful to suppress.
+		return C.bool(false)
+	}
+
+	key, ok := darwinKeycodeToKey[keycode]
+	if !ok {
+		return C.bool(false)
+	}
+
+	suppress := h.dispatch(KeyEvent{
+		Key:  key,
+		Mods: mods,
+		Down: typ == C.kCGEventKeyDown,
+		Time: time.Since(h.startTime),
+	})
+	return C.bool(suppress)
+}
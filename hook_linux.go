@@ -0,0 +1,112 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build linux
+
+package hotkey
+
+/*
+#cgo LDFLAGS: -lX11
+#include <X11/Xlib.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"time"
+)
+
+// hookPollInterval is how often the full keymap is sampled. Like
+// Watcher, Hook polls XQueryKeymap instead of grabbing keys so it never
+// takes a key away from whichever window has focus.
+const hookPollInterval = 8 * time.Millisecond
+
+type platformHook struct {
+	display *C.Display
+	done    chan struct{}
+}
+
+func (h *Hook) start() error {
+	display := C.XOpenDisplay(nil)
+	if display == nil {
+		return errors.New("hotkey: cannot open X11 display")
+	}
+	h.display = display
+	h.done = make(chan struct{})
+	go h.poll()
+	return nil
+}
+
+func (h *Hook) stop() error {
+	if h.display == nil {
+		return nil
+	}
+	close(h.done)
+	C.XCloseDisplay(h.display)
+	h.display = nil
+	return nil
+}
+
+// poll samples every key this package knows about (x11KeyKeysyms, the
+// same table Register's X11 backend uses) plus the held modifiers, and
+// reports each key that changed state since the previous sample, with
+// the held modifiers attached. Modifier changes on their own are not
+// reported. Because
+// this is a poll rather than a grab, a filter that returns true is
+// reported back to the caller but cannot actually suppress the key: by
+// the time a sample would let us know a key is down, every other
+// listener (including the window with focus) has already seen it too.
+func (h *Hook) poll() {
+	ticker := time.NewTicker(hookPollInterval)
+	defer ticker.Stop()
+
+	var keymap [32]C.char
+	down := make(map[Key]bool, len(x11KeyKeysyms))
+	start := time.Now()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+		}
+
+		C.XQueryKeymap(h.display, &keymap[0])
+		mods := h.sampleMods(keymap)
+		for key, keysym := range x11KeyKeysyms {
+			kc := C.XKeysymToKeycode(h.display, keysym)
+			if kc == 0 {
+				continue
+			}
+			isDown := keymap[kc/8]&(1<<(kc%8)) != 0
+			if isDown == down[key] {
+				continue
+			}
+			down[key] = isDown
+			h.dispatch(KeyEvent{Key: key, Mods: mods, Down: isDown, Time: time.Since(start)})
+		}
+	}
+}
+
+// sampleMods reads the held state of every modifier this package
+// tracks out of an already-sampled keymap, reusing Watcher's keysym
+// table.
+func (h *Hook) sampleMods(keymap [32]C.char) Modifier {
+	var mods Modifier
+	for mod, keysyms := range watcherModifierKeysyms {
+		for _, ks := range keysyms {
+			kc := C.XKeysymToKeycode(h.display, ks)
+			if kc == 0 {
+				continue
+			}
+			if keymap[kc/8]&(1<<(kc%8)) != 0 {
+				mods |= mod
+				break
+			}
+		}
+	}
+	return mods
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build windows
+
+package hotkey
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// kbdllhookstruct mirrors the Win32 KBDLLHOOKSTRUCT passed to a
+// WH_KEYBOARD_LL hook procedure.
+type kbdllhookstruct struct {
+	VkCode    uint32
+	ScanCode  uint32
+	Flags     uint32
+	Time      uint32
+	ExtraInfo uintptr
+}
+
+const (
+	wmKeyDown    = 0x0100
+	wmKeyUp      = 0x0101
+	wmSysKeyDown = 0x0104
+	wmSysKeyUp   = 0x0105
+)
+
+// windowsKeycodeToKey maps the virtual-key codes WH_KEYBOARD_LL reports
+// to this package's Key constants, covering every key in KeyMap.
+var windowsKeycodeToKey = map[uint32]Key{
+	0x20: KeySpace,
+	0x30: Key0, 0x31: Key1, 0x32: Key2, 0x33: Key3, 0x34: Key4,
+	0x35: Key5, 0x36: Key6, 0x37: Key7, 0x38: Key8, 0x39: Key9,
+	0x41: KeyA, 0x42: KeyB, 0x43: KeyC, 0x44: KeyD, 0x45: KeyE,
+	0x46: KeyF, 0x47: KeyG, 0x48: KeyH, 0x49: KeyI, 0x4A: KeyJ,
+	0x4B: KeyK, 0x4C: KeyL, 0x4D: KeyM, 0x4E: KeyN, 0x4F: KeyO,
+	0x50: KeyP, 0x51: KeyQ, 0x52: KeyR, 0x53: KeyS, 0x54: KeyT,
+	0x55: KeyU, 0x56: KeyV, 0x57: KeyW, 0x58: KeyX, 0x59: KeyY, 0x5A: KeyZ,
+
+	0x0D: KeyReturn, 0x1B: KeyEscape, 0x2E: KeyDelete, 0x09: KeyTab,
+
+	0x25: KeyLeft, 0x27: KeyRight, 0x26: KeyUp, 0x28: KeyDown,
+
+	0x70: KeyF1, 0x71: KeyF2, 0x72: KeyF3, 0x73: KeyF4, 0x74: KeyF5,
+	0x75: KeyF6, 0x76: KeyF7, 0x77: KeyF8, 0x78: KeyF9, 0x79: KeyF10,
+	0x7A: KeyF11, 0x7B: KeyF12, 0x7C: KeyF13, 0x7D: KeyF14, 0x7E: KeyF15,
+	0x7F: KeyF16, 0x80: KeyF17, 0x81: KeyF18, 0x82: KeyF19, 0x83: KeyF20,
+}
+
+type platformHook struct {
+	hook uintptr
+}
+
+var (
+	hookHandlesMu sync.Mutex
+	activeHooks   = map[*Hook]bool{}
+	hookStartTime time.Time
+	hookStartOnce sync.Once
+)
+
+func (h *Hook) start() error {
+	hookStartOnce.Do(func() { hookStartTime = time.Now() })
+
+	mod, _, _ := procGetModuleHandleW.Call(0)
+	hook, _, err := procSetWindowsHookExW.Call(
+		uintptr(whKeyboardLL),
+		syscall.NewCallback(hookProc),
+		mod,
+		0,
+	)
+	if hook == 0 {
+		return errors.New("hotkey: SetWindowsHookExW failed: " + err.Error())
+	}
+
+	h.hook = hook
+
+	hookHandlesMu.Lock()
+	activeHooks[h] = true
+	hookHandlesMu.Unlock()
+	return nil
+}
+
+func (h *Hook) stop() error {
+	if h.hook == 0 {
+		return nil
+	}
+	procUnhookWindowsHookEx.Call(h.hook)
+
+	hookHandlesMu.Lock()
+	delete(activeHooks, h)
+	hookHandlesMu.Unlock()
+
+	h.hook = 0
+	return nil
+}
+
+// hookProc is the shared WH_KEYBOARD_LL procedure for every active
+// Hook. Returning a non-zero value from a low-level keyboard hook is
+// what tells Windows to drop the keystroke instead of delivering it
+// further, which is how Hook can suppress a key where the platform
+// allows it.
+func hookProc(nCode int32, wParam uintptr, lParam uintptr) uintptr {
+	if nCode >= 0 {
+		kb := (*kbdllhookstruct)(unsafe.Pointer(lParam))
+		key, ok := windowsKeycodeToKey[kb.VkCode]
+		if ok {
+			down := wParam == wmKeyDown || wParam == wmSysKeyDown
+			e := KeyEvent{
+				Key:  key,
+				Down: down,
+				Time: time.Since(hookStartTime),
+			}
+			for mod, vk := range windowsModifierVKs {
+				state, _, _ := procGetAsyncKeyState.Call(vk)
+				if state&0x8000 != 0 {
+					e.Mods |= mod
+				}
+			}
+
+			hookHandlesMu.Lock()
+			hooks := make([]*Hook, 0, len(activeHooks))
+			for h := range activeHooks {
+				hooks = append(hooks, h)
+			}
+			hookHandlesMu.Unlock()
+
+			suppress := false
+			for _, h := range hooks {
+				if h.dispatch(e) {
+					suppress = true
+				}
+			}
+			if suppress {
+				return 1
+			}
+		}
+	}
+
+	ret, _, _ := procCallNextHookEx.Call(0, uintptr(nCode), wParam, lParam)
+	return ret
+}
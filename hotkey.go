@@ -40,7 +40,6 @@
 package hotkey
 
 import (
-	"fmt"
 	"runtime"
 )
 
@@ -62,16 +61,18 @@ type Hotkey struct {
 
 // New creates a new hotkey for the given modifiers and keycode.
 func New(mods []Modifier, key Key) *Hotkey {
-	keydownIn, keydownOut := newEventChan()
-	keyupIn, keyupOut := newEventChan()
-	hk := &Hotkey{
-		mods:       mods,
-		key:        key,
-		keydownIn:  keydownIn,
-		keydownOut: keydownOut,
-		keyupIn:    keyupIn,
-		keyupOut:   keyupOut,
-	}
+	hk := &Hotkey{mods: mods, key: key}
+	hk.init()
+	return hk
+}
+
+// init wires up hk's event channels and finalizer in place, so that
+// callers which must populate an already-allocated *Hotkey (such as
+// UnmarshalText) can do so without allocating and discarding a second,
+// finalizer-owning Hotkey.
+func (hk *Hotkey) init() {
+	hk.keydownIn, hk.keydownOut = newEventChan()
+	hk.keyupIn, hk.keyupOut = newEventChan()
 
 	// Make sure the hotkey is unregistered when the created
 	// hotkey is garbage collected.
@@ -81,7 +82,6 @@ func New(mods []Modifier, key Key) *Hotkey {
 		close(hk.keydownIn)
 		close(hk.keyupIn)
 	})
-	return hk
 }
 
 // Register registers a combination of hotkeys. If the hotkey has
@@ -110,22 +110,20 @@ func (hk *Hotkey) Unregister() error {
 	return nil
 }
 
-// String returns a string representation of the hotkey.
-func (hk *Hotkey) String() string {
-	s := fmt.Sprintf("%v", hk.key)
-	for _, mod := range hk.mods {
-		s += fmt.Sprintf("+%v", mod)
-	}
-	return s
-}
-
 // newEventChan returns a sender and a receiver of a buffered channel
 // with infinite capacity.
-func newEventChan() (chan<- Event, <-chan Event) {
-	in, out := make(chan Event), make(chan Event)
+func newEventChan() (chan<- Event, <-chan Event) { return newChan[Event]() }
+
+// newChan returns a sender and a receiver of a buffered channel with
+// infinite capacity: sends on in never block, and values are delivered
+// to out in order. Watcher and Hook reuse this instead of each pasting
+// their own copy for ModifiersEvent and KeyEvent respectively.
+func newChan[T any]() (chan<- T, <-chan T) {
+	in, out := make(chan T), make(chan T)
 
 	go func() {
-		var q []Event
+		var zero T
+		var q []T
 
 		for {
 			e, ok := <-in
@@ -137,7 +135,7 @@ func newEventChan() (chan<- Event, <-chan Event) {
 			for len(q) > 0 {
 				select {
 				case out <- q[0]:
-					q[0] = Event{}
+					q[0] = zero
 					q = q[1:]
 				case e, ok := <-in:
 					if ok {
@@ -0,0 +1,475 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build linux
+
+package hotkey
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// platformHotkey dispatches to whichever Linux backend suits the running
+// session: a direct X11 grab under Xorg (and XWayland), or the
+// freedesktop GlobalShortcuts portal under a native Wayland compositor,
+// where X11 key grabs are not delivered (see portalHotkey below).
+type platformHotkey struct {
+	x11    *x11Hotkey
+	portal *portalHotkey
+}
+
+func (hk *Hotkey) register() error {
+	if isWayland() {
+		p, err := newPortalHotkey(hk.mods, hk.key, hk.keydownIn, hk.keyupIn)
+		if err != nil {
+			return fmt.Errorf("hotkey: failed to register via portal: %w", err)
+		}
+		hk.portal = p
+		return nil
+	}
+
+	x, err := newX11Hotkey(hk.mods, hk.key, hk.keydownIn, hk.keyupIn)
+	if err != nil {
+		return fmt.Errorf("hotkey: failed to register via X11: %w", err)
+	}
+	hk.x11 = x
+	return nil
+}
+
+func (hk *Hotkey) unregister() error {
+	switch {
+	case hk.portal != nil:
+		err := hk.portal.close()
+		hk.portal = nil
+		return err
+	case hk.x11 != nil:
+		err := hk.x11.close()
+		hk.x11 = nil
+		return err
+	}
+	return nil
+}
+
+// isWayland reports whether the current session is a native Wayland
+// session, as opposed to Xorg or an XWayland-backed session. Detection
+// follows the same heuristic used by most portal-aware toolkits: a
+// WAYLAND_DISPLAY environment variable is present, and XDG_SESSION_TYPE
+// (when set) says so explicitly.
+func isWayland() bool {
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return false
+	}
+	if st := os.Getenv("XDG_SESSION_TYPE"); st != "" && st != "wayland" {
+		return false
+	}
+	return true
+}
+
+// portalHotkey registers a single global shortcut through the
+// org.freedesktop.portal.GlobalShortcuts portal, which both GNOME and
+// KDE implement under Wayland via xdg-desktop-portal. Where the portal
+// itself is unavailable, registration falls back to kdeGlobalAccelHotkey,
+// KDE's own long-standing org.kde.kglobalaccel D-Bus service; see its
+// doc comment for why there is no equivalent GNOME fallback.
+type portalHotkey struct {
+	conn       *dbus.Conn
+	session    dbus.ObjectPath
+	shortcutID string
+	signal     chan *dbus.Signal
+	done       chan struct{}
+
+	kde *kdeGlobalAccelHotkey
+}
+
+const (
+	portalBusName      = "org.freedesktop.portal.Desktop"
+	portalObjectPath   = "/org/freedesktop/portal/desktop"
+	portalIface        = "org.freedesktop.portal.GlobalShortcuts"
+	portalRequestIface = "org.freedesktop.portal.Request"
+
+	// portalRequestTimeout bounds how long newPortalHotkey waits for a
+	// portal method's asynchronous Request.Response signal before
+	// concluding the portal isn't going to answer.
+	portalRequestTimeout = 5 * time.Second
+)
+
+// portalRequestTokenSeq generates unique handle_token values for
+// portalCall, so concurrent registrations on the same connection don't
+// collide on the same predicted request object path.
+var portalRequestTokenSeq atomic.Uint64
+
+func newPortalHotkey(mods []Modifier, key Key, keydownIn, keyupIn chan<- Event) (*portalHotkey, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	shortcutID := fmt.Sprintf("golang-design-hotkey-%v", shortcutLabel(mods, key))
+	obj := conn.Object(portalBusName, portalObjectPath)
+
+	sessionPath, err := portalCreateSession(conn, obj)
+	if err != nil {
+		conn.Close()
+		k, kerr := newKDEGlobalAccelHotkey(mods, key, shortcutID, keydownIn, keyupIn)
+		if kerr != nil {
+			return nil, fmt.Errorf("GlobalShortcuts portal unavailable (%v), and kglobalaccel "+
+				"fallback also failed: %w", err, kerr)
+		}
+		return &portalHotkey{kde: k}, nil
+	}
+
+	if err := portalBindShortcuts(conn, obj, sessionPath, shortcutID); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("hotkey: portal BindShortcuts failed: %w", err)
+	}
+
+	sig := make(chan *dbus.Signal, 16)
+	conn.Signal(sig)
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface(portalIface),
+		dbus.WithMatchObjectPath(sessionPath),
+	); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	p := &portalHotkey{
+		conn:       conn,
+		session:    sessionPath,
+		shortcutID: shortcutID,
+		signal:     sig,
+		done:       make(chan struct{}),
+	}
+	go p.dispatch(keydownIn, keyupIn)
+	return p, nil
+}
+
+// portalCreateSession calls GlobalShortcuts.CreateSession and returns
+// the session_handle from its Response, following the request/response
+// handshake below.
+func portalCreateSession(conn *dbus.Conn, obj dbus.BusObject) (dbus.ObjectPath, error) {
+	results, err := portalCall(conn, obj, portalIface+".CreateSession", func(token string) []interface{} {
+		return []interface{}{map[string]dbus.Variant{
+			"handle_token":         dbus.MakeVariant(token),
+			"session_handle_token": dbus.MakeVariant(token),
+		}}
+	})
+	if err != nil {
+		return "", err
+	}
+	handle, ok := results["session_handle"].Value().(string)
+	if !ok {
+		return "", fmt.Errorf("hotkey: CreateSession response missing session_handle")
+	}
+	return dbus.ObjectPath(handle), nil
+}
+
+// portalBindShortcuts calls GlobalShortcuts.BindShortcuts for a single
+// shortcut on an already-created session.
+func portalBindShortcuts(conn *dbus.Conn, obj dbus.BusObject, session dbus.ObjectPath, shortcutID string) error {
+	_, err := portalCall(conn, obj, portalIface+".BindShortcuts", func(token string) []interface{} {
+		return []interface{}{
+			session,
+			[]map[string]dbus.Variant{{
+				"shortcut_id": dbus.MakeVariant(shortcutID),
+				"description": dbus.MakeVariant(shortcutID),
+			}},
+			"",
+			map[string]dbus.Variant{"handle_token": dbus.MakeVariant(token)},
+		}
+	})
+	return err
+}
+
+// portalCall invokes a portal method that follows the freedesktop.org
+// Request/Response convention used by every xdg-desktop-portal
+// interface: the method itself returns only a Request object path
+// synchronously, and the actual results arrive later as that object's
+// org.freedesktop.portal.Request.Response signal.
+//
+// To avoid racing a response that arrives before we'd otherwise
+// subscribe to it, buildArgs is asked to embed a "handle_token" in the
+// call's options, which lets us predict the Request's object path (per
+// the spec: /org/freedesktop/portal/desktop/request/<sender>/<token>,
+// sender being our own unique bus name with ':' dropped and '.' turned
+// into '_') and subscribe to it before the method call is even made.
+func portalCall(conn *dbus.Conn, obj dbus.BusObject, method string, buildArgs func(token string) []interface{}) (map[string]dbus.Variant, error) {
+	token := fmt.Sprintf("hotkey%d", portalRequestTokenSeq.Add(1))
+	reqPath := portalPredictedRequestPath(conn, token)
+
+	sig := make(chan *dbus.Signal, 1)
+	conn.Signal(sig)
+	defer conn.RemoveSignal(sig)
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchInterface(portalRequestIface),
+		dbus.WithMatchObjectPath(reqPath),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return nil, err
+	}
+	defer conn.RemoveMatchSignal(match...)
+
+	call := obj.Call(method, 0, buildArgs(token)...)
+	if call.Err != nil {
+		return nil, call.Err
+	}
+	var actualPath dbus.ObjectPath
+	if err := call.Store(&actualPath); err != nil {
+		return nil, err
+	}
+	if actualPath != reqPath {
+		// The portal handed back a different request path than ours
+		// predicted (allowed by the spec if our token happened to
+		// collide with one already in flight); subscribe to the real
+		// one instead.
+		reqPath = actualPath
+		realMatch := []dbus.MatchOption{
+			dbus.WithMatchInterface(portalRequestIface),
+			dbus.WithMatchObjectPath(reqPath),
+		}
+		if err := conn.AddMatchSignal(realMatch...); err != nil {
+			return nil, err
+		}
+		defer conn.RemoveMatchSignal(realMatch...)
+	}
+
+	timeout := time.NewTimer(portalRequestTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case s := <-sig:
+			if s.Path != reqPath || s.Name != portalRequestIface+".Response" {
+				continue
+			}
+			if len(s.Body) < 2 {
+				return nil, fmt.Errorf("hotkey: malformed %s from %s", portalRequestIface+".Response", reqPath)
+			}
+			code, _ := s.Body[0].(uint32)
+			if code != 0 {
+				return nil, fmt.Errorf("hotkey: portal request %s denied (response code %d)", method, code)
+			}
+			results, _ := s.Body[1].(map[string]dbus.Variant)
+			return results, nil
+		case <-timeout.C:
+			return nil, fmt.Errorf("hotkey: timed out waiting for %s response", method)
+		}
+	}
+}
+
+// portalPredictedRequestPath computes the Request object path a portal
+// method call with the given handle_token will respond on, per the
+// freedesktop.org portal spec.
+func portalPredictedRequestPath(conn *dbus.Conn, token string) dbus.ObjectPath {
+	sender := strings.TrimPrefix(string(conn.Names()[0]), ":")
+	sender = strings.ReplaceAll(sender, ".", "_")
+	return dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/portal/desktop/request/%s/%s", sender, token))
+}
+
+// dispatch translates the portal's Activated/Deactivated signals into
+// the Hotkey's Keydown/Keyup channels.
+func (p *portalHotkey) dispatch(keydownIn, keyupIn chan<- Event) {
+	for {
+		select {
+		case <-p.done:
+			return
+		case sig, ok := <-p.signal:
+			if !ok {
+				return
+			}
+			if len(sig.Body) == 0 {
+				continue
+			}
+			id, ok := sig.Body[0].(string)
+			if !ok || id != p.shortcutID {
+				continue
+			}
+			switch sig.Name {
+			case portalIface + ".Activated":
+				keydownIn <- Event{}
+			case portalIface + ".Deactivated":
+				keyupIn <- Event{}
+			}
+		}
+	}
+}
+
+// shortcutLabel renders a stable, human-readable identifier for a
+// modifiers+key combination, used as the portal's shortcut_id.
+func shortcutLabel(mods []Modifier, key Key) string {
+	s := fmt.Sprintf("%v", key)
+	for _, mod := range mods {
+		s += fmt.Sprintf("+%v", mod)
+	}
+	return s
+}
+
+func (p *portalHotkey) close() error {
+	if p.kde != nil {
+		return p.kde.close()
+	}
+	close(p.done)
+	obj := p.conn.Object(portalBusName, portalObjectPath)
+	obj.Call(portalIface+".UnbindShortcuts", 0, p.session, []string{p.shortcutID})
+	return p.conn.Close()
+}
+
+// kdeGlobalAccelHotkey registers a global shortcut through KDE's own
+// org.kde.kglobalaccel D-Bus service, used as a fallback when the
+// GlobalShortcuts portal is unavailable.
+//
+// There is deliberately no equivalent GNOME fallback: unlike KDE,
+// stock GNOME Shell has never exposed a stable, documented D-Bus
+// interface for registering a global shortcut from an arbitrary
+// application outside the portal — that gap is the entire reason the
+// portal exists. Inventing a call against some extension's private
+// D-Bus API would only work for users of that specific extension and
+// silently fail for everyone else, which is worse than failing loudly;
+// on GNOME, if the portal is unavailable, registration simply fails.
+type kdeGlobalAccelHotkey struct {
+	conn     *dbus.Conn
+	actionID []string
+	signal   chan *dbus.Signal
+	done     chan struct{}
+}
+
+const (
+	kdeBusName    = "org.kde.kglobalaccel"
+	kdeObjectPath = "/kglobalaccel"
+	kdeIface      = "org.kde.KGlobalAccel"
+
+	// Qt::Key values and modifier bits used by setShortcut's key
+	// sequence, per qnamespace.h.
+	qtShiftModifier = 0x02000000
+	qtCtrlModifier  = 0x04000000
+	qtAltModifier   = 0x08000000
+	qtMetaModifier  = 0x10000000
+)
+
+// kdeQtModifierBits maps this package's modifiers to the Qt modifier
+// bits kglobalaccel's setShortcut expects them OR'd into the key value.
+var kdeQtModifierBits = map[Modifier]int{
+	ModCtrl:   qtCtrlModifier,
+	ModShift:  qtShiftModifier,
+	ModOption: qtAltModifier,
+	ModCmd:    qtMetaModifier,
+}
+
+// kdeQtKeyCodes maps this package's keys to their Qt::Key value. Letters
+// and digits share ASCII's codes; everything else has its own Qt::Key_*
+// constant.
+var kdeQtKeyCodes = map[Key]int{
+	KeySpace: 0x20,
+	Key0:     0x30, Key1: 0x31, Key2: 0x32, Key3: 0x33, Key4: 0x34,
+	Key5: 0x35, Key6: 0x36, Key7: 0x37, Key8: 0x38, Key9: 0x39,
+	KeyA: 0x41, KeyB: 0x42, KeyC: 0x43, KeyD: 0x44, KeyE: 0x45,
+	KeyF: 0x46, KeyG: 0x47, KeyH: 0x48, KeyI: 0x49, KeyJ: 0x4A,
+	KeyK: 0x4B, KeyL: 0x4C, KeyM: 0x4D, KeyN: 0x4E, KeyO: 0x4F,
+	KeyP: 0x50, KeyQ: 0x51, KeyR: 0x52, KeyS: 0x53, KeyT: 0x54,
+	KeyU: 0x55, KeyV: 0x56, KeyW: 0x57, KeyX: 0x58, KeyY: 0x59, KeyZ: 0x5A,
+
+	KeyEscape: 0x01000000, KeyTab: 0x01000001, KeyReturn: 0x01000004,
+	KeyDelete: 0x01000007,
+
+	KeyUp: 0x01000013, KeyDown: 0x01000015, KeyLeft: 0x01000012, KeyRight: 0x01000014,
+
+	KeyF1: 0x01000030, KeyF2: 0x01000031, KeyF3: 0x01000032, KeyF4: 0x01000033,
+	KeyF5: 0x01000034, KeyF6: 0x01000035, KeyF7: 0x01000036, KeyF8: 0x01000037,
+	KeyF9: 0x01000038, KeyF10: 0x01000039, KeyF11: 0x0100003A, KeyF12: 0x0100003B,
+	KeyF13: 0x0100003C, KeyF14: 0x0100003D, KeyF15: 0x0100003E, KeyF16: 0x0100003F,
+	KeyF17: 0x01000040, KeyF18: 0x01000041, KeyF19: 0x01000042, KeyF20: 0x01000043,
+}
+
+func newKDEGlobalAccelHotkey(mods []Modifier, key Key, shortcutID string, keydownIn, keyupIn chan<- Event) (*kdeGlobalAccelHotkey, error) {
+	qtKey, ok := kdeQtKeyCodes[key]
+	if !ok {
+		return nil, fmt.Errorf("hotkey: key %v has no kglobalaccel mapping", key)
+	}
+	for _, mod := range mods {
+		bit, ok := kdeQtModifierBits[mod]
+		if !ok {
+			return nil, fmt.Errorf("hotkey: modifier %v has no kglobalaccel mapping", mod)
+		}
+		qtKey |= bit
+	}
+
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	// actionId is always exactly [componentUnique, actionUnique,
+	// componentFriendly, actionFriendly] for kglobalaccel.
+	actionID := []string{"golang-design-hotkey", shortcutID, "golang.design/x/hotkey", shortcutID}
+
+	obj := conn.Object(kdeBusName, kdeObjectPath)
+	if call := obj.Call(kdeIface+".doRegister", 0, actionID); call.Err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("kglobalaccel doRegister failed: %w", call.Err)
+	}
+	if call := obj.Call(kdeIface+".setShortcut", 0, actionID, []int32{int32(qtKey)}, uint32(0x2)); call.Err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("kglobalaccel setShortcut failed: %w", call.Err)
+	}
+
+	sig := make(chan *dbus.Signal, 16)
+	conn.Signal(sig)
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface(kdeIface+".Component"),
+		dbus.WithMatchMember("globalShortcutPressed"),
+	); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	k := &kdeGlobalAccelHotkey{
+		conn:     conn,
+		actionID: actionID,
+		signal:   sig,
+		done:     make(chan struct{}),
+	}
+	// kglobalaccel only ever signals globalShortcutPressed: there is no
+	// corresponding "released" signal, so keyupIn is never sent to on
+	// this backend. Applications that need Keyup on KDE without the
+	// portal are, for now, out of luck; that limitation is inherent to
+	// kglobalaccel, not something this package works around.
+	go k.dispatch(keydownIn)
+	return k, nil
+}
+
+func (k *kdeGlobalAccelHotkey) dispatch(keydownIn chan<- Event) {
+	for {
+		select {
+		case <-k.done:
+			return
+		case sig, ok := <-k.signal:
+			if !ok {
+				return
+			}
+			if len(sig.Body) < 2 {
+				continue
+			}
+			actionUnique, ok := sig.Body[0].(string)
+			if !ok || actionUnique != k.actionID[1] {
+				continue
+			}
+			keydownIn <- Event{}
+		}
+	}
+}
+
+func (k *kdeGlobalAccelHotkey) close() error {
+	close(k.done)
+	obj := k.conn.Object(kdeBusName, kdeObjectPath)
+	obj.Call(kdeIface+".unregister", 0, k.actionID[0], k.actionID[1])
+	return k.conn.Close()
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build linux
+
+package hotkey
+
+/*
+#cgo LDFLAGS: -lX11
+#include <X11/Xlib.h>
+#include <X11/keysym.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// x11KeyKeysyms maps this package's Key constants to the X11 keysym
+// used to look up the corresponding keycode on the running X server.
+var x11KeyKeysyms = map[Key]C.KeySym{
+	KeySpace: C.XK_space,
+	Key1:     C.XK_1, Key2: C.XK_2, Key3: C.XK_3, Key4: C.XK_4, Key5: C.XK_5,
+	Key6: C.XK_6, Key7: C.XK_7, Key8: C.XK_8, Key9: C.XK_9, Key0: C.XK_0,
+	KeyA: C.XK_a, KeyB: C.XK_b, KeyC: C.XK_c, KeyD: C.XK_d, KeyE: C.XK_e,
+	KeyF: C.XK_f, KeyG: C.XK_g, KeyH: C.XK_h, KeyI: C.XK_i, KeyJ: C.XK_j,
+	KeyK: C.XK_k, KeyL: C.XK_l, KeyM: C.XK_m, KeyN: C.XK_n, KeyO: C.XK_o,
+	KeyP: C.XK_p, KeyQ: C.XK_q, KeyR: C.XK_r, KeyS: C.XK_s, KeyT: C.XK_t,
+	KeyU: C.XK_u, KeyV: C.XK_v, KeyW: C.XK_w, KeyX: C.XK_x, KeyY: C.XK_y,
+	KeyZ: C.XK_z,
+
+	KeyReturn: C.XK_Return,
+	KeyEscape: C.XK_Escape,
+	KeyDelete: C.XK_Delete,
+	KeyTab:    C.XK_Tab,
+
+	KeyLeft:  C.XK_Left,
+	KeyRight: C.XK_Right,
+	KeyUp:    C.XK_Up,
+	KeyDown:  C.XK_Down,
+
+	KeyF1: C.XK_F1, KeyF2: C.XK_F2, KeyF3: C.XK_F3, KeyF4: C.XK_F4,
+	KeyF5: C.XK_F5, KeyF6: C.XK_F6, KeyF7: C.XK_F7, KeyF8: C.XK_F8,
+	KeyF9: C.XK_F9, KeyF10: C.XK_F10, KeyF11: C.XK_F11, KeyF12: C.XK_F12,
+	KeyF13: C.XK_F13, KeyF14: C.XK_F14, KeyF15: C.XK_F15, KeyF16: C.XK_F16,
+	KeyF17: C.XK_F17, KeyF18: C.XK_F18, KeyF19: C.XK_F19, KeyF20: C.XK_F20,
+}
+
+// x11IgnoredModMasks are the combinations of "don't care" modifier bits
+// (NumLock, CapsLock) a grab must be repeated under: X11 reports a key
+// event's state including whichever of these happen to be toggled on,
+// so a single XGrabKey call for the hotkey's own modifiers would miss
+// presses made with NumLock or CapsLock active.
+var x11IgnoredModMasks = []C.uint{
+	0,
+	C.LockMask,
+	C.Mod2Mask,
+	C.LockMask | C.Mod2Mask,
+}
+
+// x11Hotkey grabs a single combination of modifiers and key directly
+// from the X server via XGrabKey. It is the backend used under Xorg and
+// XWayland; see hotkey_linux.go for the Wayland portal alternative.
+type x11Hotkey struct {
+	display *C.Display
+	root    C.Window
+	keycode C.KeyCode
+	state   C.uint
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newX11Hotkey(mods []Modifier, key Key, keydownIn, keyupIn chan<- Event) (*x11Hotkey, error) {
+	keysym, ok := x11KeyKeysyms[key]
+	if !ok {
+		return nil, fmt.Errorf("hotkey: key %v has no X11 mapping", key)
+	}
+
+	display := C.XOpenDisplay(nil)
+	if display == nil {
+		return nil, errors.New("hotkey: cannot open X11 display")
+	}
+
+	keycode := C.XKeysymToKeycode(display, keysym)
+	if keycode == 0 {
+		C.XCloseDisplay(display)
+		return nil, fmt.Errorf("hotkey: key %v has no keycode on this X server", key)
+	}
+
+	root := C.XDefaultRootWindow(display)
+	state := x11ModifierState(mods)
+	for _, ignored := range x11IgnoredModMasks {
+		C.XGrabKey(display, C.int(keycode), state|ignored, root, C.True, C.GrabModeAsync, C.GrabModeAsync)
+	}
+	C.XSelectInput(display, root, C.KeyPressMask|C.KeyReleaseMask)
+
+	x := &x11Hotkey{
+		display: display,
+		root:    root,
+		keycode: keycode,
+		state:   state,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go x.loop(keydownIn, keyupIn)
+	return x, nil
+}
+
+// x11ModifierState translates this package's modifiers into the X11
+// modifier mask XGrabKey expects.
+func x11ModifierState(mods []Modifier) C.uint {
+	var state C.uint
+	for _, mod := range mods {
+		switch mod {
+		case ModCtrl:
+			state |= C.ControlMask
+		case ModShift:
+			state |= C.ShiftMask
+		case ModOption:
+			state |= C.Mod1Mask
+		case ModCmd:
+			state |= C.Mod4Mask
+		}
+	}
+	return state
+}
+
+// loop reads events off the display connection until close signals it
+// to stop, forwarding presses and releases of the grabbed key. It polls
+// XPending rather than blocking in XNextEvent so that close can wait
+// for the loop to actually exit before tearing down the display
+// connection it reads from.
+func (x *x11Hotkey) loop(keydownIn, keyupIn chan<- Event) {
+	defer close(x.stopped)
+
+	var ev C.XEvent
+	for {
+		select {
+		case <-x.done:
+			return
+		default:
+		}
+
+		if C.XPending(x.display) == 0 {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		C.XNextEvent(x.display, &ev)
+		// XEvent is a C union; cgo represents it as an opaque byte
+		// array with no fields. XKeyEvent shares XAnyEvent's layout
+		// (type/serial/send_event/display/window first), so the cast
+		// key is used for both the type tag and the keycode instead
+		// of reading fields off ev directly.
+		key := (*C.XKeyEvent)(unsafe.Pointer(&ev))
+		if C.uint(key.keycode) != C.uint(x.keycode) {
+			continue
+		}
+		switch key._type {
+		case C.KeyPress:
+			keydownIn <- Event{}
+		case C.KeyRelease:
+			keyupIn <- Event{}
+		}
+	}
+}
+
+func (x *x11Hotkey) close() error {
+	close(x.done)
+	<-x.stopped
+
+	for _, ignored := range x11IgnoredModMasks {
+		C.XUngrabKey(x.display, C.int(x.keycode), x.state|ignored, x.root)
+	}
+	C.XCloseDisplay(x.display)
+	return nil
+}
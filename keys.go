@@ -0,0 +1,142 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey
+
+// Modifier represents a modifier key that, combined with a Key, forms a
+// Hotkey. Modifiers are platform neutral: each platform-specific
+// register() backend translates them into whatever the OS expects
+// (e.g. ModCmd becomes Mod4Mask under X11, the Windows key's virtual-key
+// code on Windows, or the Command flag on macOS).
+type Modifier int
+
+// The modifiers this package understands. Each is a distinct bit, so
+// several can be OR-ed into one Modifier value, as KeyEvent.Mods does.
+const (
+	ModCtrl Modifier = 1 << iota
+	ModOption
+	ModShift
+	ModCmd
+)
+
+// String implements fmt.Stringer, returning the constant's Go identifier
+// (e.g. "ModCtrl"). Accelerator rendering uses modifierName instead,
+// which prints the canonical accelerator spelling (e.g. "Ctrl").
+func (m Modifier) String() string {
+	switch m {
+	case ModCtrl:
+		return "ModCtrl"
+	case ModOption:
+		return "ModOption"
+	case ModShift:
+		return "ModShift"
+	case ModCmd:
+		return "ModCmd"
+	default:
+		return "ModUnknown"
+	}
+}
+
+// Key represents a single key that, combined with one or more
+// Modifiers, forms a Hotkey. Like Modifier, Key is platform neutral: it
+// is translated into a platform-specific code (an X11 keysym, a macOS
+// virtual keycode, a Windows virtual-key code, ...) by whichever
+// platform's KeyMap-derived table the current register() or Hook
+// backend uses.
+type Key int
+
+// The keys this package understands. See KeyMap for their string
+// spellings.
+const (
+	KeySpace Key = iota
+	Key1
+	Key2
+	Key3
+	Key4
+	Key5
+	Key6
+	Key7
+	Key8
+	Key9
+	Key0
+	KeyA
+	KeyB
+	KeyC
+	KeyD
+	KeyE
+	KeyF
+	KeyG
+	KeyH
+	KeyI
+	KeyJ
+	KeyK
+	KeyL
+	KeyM
+	KeyN
+	KeyO
+	KeyP
+	KeyQ
+	KeyR
+	KeyS
+	KeyT
+	KeyU
+	KeyV
+	KeyW
+	KeyX
+	KeyY
+	KeyZ
+
+	KeyReturn
+	KeyEscape
+	KeyDelete
+	KeyTab
+
+	KeyLeft
+	KeyRight
+	KeyUp
+	KeyDown
+
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyF13
+	KeyF14
+	KeyF15
+	KeyF16
+	KeyF17
+	KeyF18
+	KeyF19
+	KeyF20
+)
+
+// keyNames is the reverse of KeyMap, built once so String doesn't have
+// to scan the whole map on every call.
+var keyNames = func() map[Key]string {
+	m := make(map[Key]string, len(KeyMap))
+	for name, key := range KeyMap {
+		m[key] = name
+	}
+	return m
+}()
+
+// String implements fmt.Stringer, returning the constant's Go identifier
+// (e.g. "KeyS", "KeyF5"). keyName and Key.MarshalText rely on this form
+// to render the accelerator spelling by trimming the "Key" prefix.
+func (k Key) String() string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	return "KeyUnknown"
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultStepTimeout is the duration a Sequence will wait for the next
+// step's keystroke before resetting back to its first step.
+const defaultStepTimeout = 1 * time.Second
+
+// Step describes a single keystroke within a Sequence: a key pressed
+// together with a set of modifiers, such as Ctrl+K.
+type Step struct {
+	Mods []Modifier
+	Key  Key
+}
+
+// Sequence is a chorded, multi-step keyboard shortcut, such as the
+// Emacs/VS Code style "Ctrl+K Ctrl+S". A Sequence only ever registers
+// a single OS-level hotkey at a time: the hotkey for the step the
+// sequence is currently waiting on. When that step's hotkey fires,
+// Sequence unregisters it and registers the next step in its place;
+// if the whole chain completes before the timeout elapses, a signal
+// is sent on the channel returned by Triggered. A timeout resets the
+// sequence back to its first step. A different key pressed while the
+// sequence waits on a later step is not observed and does not reset it.
+type Sequence struct {
+	steps   []Step
+	timeout time.Duration
+
+	mu      sync.Mutex
+	idx     int
+	cur     *Hotkey
+	timer   *time.Timer
+	done    chan struct{}
+	trigIn  chan<- Event
+	trigOut <-chan Event
+	errs    chan error
+}
+
+// NewSequence creates a new key sequence out of the given steps. The
+// sequence must have at least two steps; timeout controls how long
+// Sequence waits for the next step's keystroke before resetting to the
+// first step. A zero timeout defaults to one second.
+func NewSequence(steps []Step, timeout time.Duration) *Sequence {
+	if timeout <= 0 {
+		timeout = defaultStepTimeout
+	}
+	trigIn, trigOut := newEventChan()
+	return &Sequence{
+		steps:   steps,
+		timeout: timeout,
+		trigIn:  trigIn,
+		trigOut: trigOut,
+		errs:    make(chan error, 8),
+	}
+}
+
+// Register starts watching for the sequence's first step. If the
+// sequence is already registered, it is torn down first and started
+// over from the first step. It returns an error if the sequence has
+// fewer than two steps.
+func (sq *Sequence) Register() error {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	if len(sq.steps) < 2 {
+		return fmt.Errorf("hotkey: sequence must have at least two steps, got %d", len(sq.steps))
+	}
+
+	if sq.done != nil {
+		close(sq.done)
+		sq.stopTimerLocked()
+		sq.teardownLocked()
+	}
+
+	sq.done = make(chan struct{})
+	sq.idx = 0
+	return sq.armLocked()
+}
+
+// Unregister stops the sequence and unregisters any hotkey it currently
+// holds.
+func (sq *Sequence) Unregister() error {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	if sq.done == nil {
+		return nil
+	}
+	close(sq.done)
+	sq.done = nil
+	sq.stopTimerLocked()
+	return sq.teardownLocked()
+}
+
+// Triggered returns a channel that receives a signal when every step of
+// the sequence has been pressed in order within each step's timeout.
+func (sq *Sequence) Triggered() <-chan Event { return sq.trigOut }
+
+// Errors returns a channel that receives an error whenever the sequence
+// fails to arm a step's hotkey after a step fired or timed out, for
+// example because another application already holds it. A failure to
+// arm the first step inside Register is returned by Register instead.
+// A reported failure leaves the sequence unregistered; call Register
+// again to retry.
+func (sq *Sequence) Errors() <-chan error { return sq.errs }
+
+// armLocked registers the hotkey for the current step and starts a
+// goroutine that watches for it firing. sq.mu must be held.
+func (sq *Sequence) armLocked() error {
+	step := sq.steps[sq.idx]
+	hk := New(step.Mods, step.Key)
+	if err := hk.Register(); err != nil {
+		return err
+	}
+	sq.cur = hk
+
+	if sq.idx > 0 {
+		sq.timer = time.AfterFunc(sq.timeout, sq.reset)
+	}
+
+	done := sq.done
+	go func() {
+		select {
+		case _, ok := <-hk.Keydown():
+			if !ok {
+				// Keydown was closed out from under us: teardownLocked
+				// (called by reset/advance/failLocked to unregister the
+				// previous step) closes and recreates hk's channels, and
+				// that close is itself what woke this select. There was
+				// no real keystroke, so don't advance.
+				return
+			}
+			sq.advance(done)
+		case <-done:
+		}
+	}()
+	return nil
+}
+
+// advance is invoked when the current step's hotkey fires. It moves the
+// sequence to its next step, or reports completion and resets to the
+// first step if this was the last one.
+func (sq *Sequence) advance(done chan struct{}) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	select {
+	case <-done:
+		return
+	default:
+	}
+	if sq.done != done {
+		return
+	}
+
+	sq.stopTimerLocked()
+	sq.teardownLocked()
+
+	sq.idx++
+	if sq.idx >= len(sq.steps) {
+		sq.idx = 0
+		if err := sq.armLocked(); err != nil {
+			sq.failLocked(err)
+			return
+		}
+		sq.trigIn <- Event{}
+		return
+	}
+	if err := sq.armLocked(); err != nil {
+		sq.failLocked(err)
+	}
+}
+
+// reset aborts the in-progress sequence and re-arms the first step.
+func (sq *Sequence) reset() {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	if sq.done == nil {
+		return
+	}
+	sq.teardownLocked()
+	sq.idx = 0
+	if err := sq.armLocked(); err != nil {
+		sq.failLocked(err)
+	}
+}
+
+// failLocked abandons the in-progress sequence after a step failed to
+// arm, tearing down any partial state and reporting err on Errors. The
+// sequence is left unregistered; the caller must call Register to
+// retry. sq.mu must be held.
+func (sq *Sequence) failLocked(err error) {
+	sq.stopTimerLocked()
+	sq.teardownLocked()
+	if sq.done != nil {
+		close(sq.done)
+		sq.done = nil
+	}
+	select {
+	case sq.errs <- err:
+	default:
+	}
+}
+
+func (sq *Sequence) stopTimerLocked() {
+	if sq.timer != nil {
+		sq.timer.Stop()
+		sq.timer = nil
+	}
+}
+
+func (sq *Sequence) teardownLocked() error {
+	if sq.cur == nil {
+		return nil
+	}
+	err := sq.cur.Unregister()
+	sq.cur = nil
+	return err
+}
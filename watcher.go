@@ -0,0 +1,122 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+package hotkey
+
+import "sync"
+
+// ModifiersEvent reports a change in the set of currently held modifier
+// keys, independent of any registered Hotkey or Sequence.
+type ModifiersEvent struct {
+	// Mods is the complete set of modifiers held immediately after
+	// this event.
+	Mods []Modifier
+	// Changed is the modifier whose state changed to produce this event.
+	Changed Modifier
+	// Down reports whether Changed was pressed (true) or released (false).
+	Down bool
+}
+
+// Watcher observes modifier key (Ctrl/Shift/Alt/Meta, ...) state changes
+// at the system level. Unlike Hotkey, a Watcher never consumes the
+// keystrokes it observes: use it for cases such as "hold Alt to preview"
+// or push-to-talk, where the held modifier itself is the signal.
+type Watcher struct {
+	platformWatcher
+
+	mu   sync.Mutex
+	mods map[Modifier]bool
+
+	changedIn  chan<- ModifiersEvent
+	changedOut <-chan ModifiersEvent
+}
+
+// NewWatcher creates a new modifier key watcher. Call Start to begin
+// observing modifier changes.
+func NewWatcher() *Watcher {
+	in, out := newChan[ModifiersEvent]()
+	return &Watcher{
+		mods:       make(map[Modifier]bool),
+		changedIn:  in,
+		changedOut: out,
+	}
+}
+
+// Start begins observing system-wide modifier key changes.
+func (w *Watcher) Start() error { return w.start() }
+
+// Stop stops observing modifier key changes.
+func (w *Watcher) Stop() error { return w.stop() }
+
+// Modifiers returns a snapshot of the modifiers currently held down.
+func (w *Watcher) Modifiers() []Modifier {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	mods := make([]Modifier, 0, len(w.mods))
+	for mod, held := range w.mods {
+		if held {
+			mods = append(mods, mod)
+		}
+	}
+	return mods
+}
+
+// ModifiersChanged returns a channel that receives an event every time
+// the set of held modifier keys changes.
+func (w *Watcher) ModifiersChanged() <-chan ModifiersEvent { return w.changedOut }
+
+// setModifier updates mod's held state and, if it actually changed,
+// notifies ModifiersChanged. It is called by the platform-specific
+// implementation, which is expected to report the current state of
+// every modifier it tracks on every sample rather than only deltas, so
+// the deduplication happens here.
+func (w *Watcher) setModifier(mod Modifier, down bool) {
+	w.mu.Lock()
+	if w.mods[mod] == down {
+		w.mu.Unlock()
+		return
+	}
+	w.mods[mod] = down
+	mods := make([]Modifier, 0, len(w.mods))
+	for m, held := range w.mods {
+		if held {
+			mods = append(mods, m)
+		}
+	}
+	w.mu.Unlock()
+
+	w.changedIn <- ModifiersEvent{Mods: mods, Changed: mod, Down: down}
+}
+
+// defaultWatcher backs the package-level Modifiers and ModifiersChanged
+// helpers below.
+var (
+	defaultWatcher     = NewWatcher()
+	defaultWatcherOnce sync.Once
+)
+
+// startDefaultWatcher starts defaultWatcher the first time it is
+// needed; later calls are no-ops.
+func startDefaultWatcher() {
+	defaultWatcherOnce.Do(func() { defaultWatcher.start() })
+}
+
+// Modifiers returns a snapshot of the modifiers currently held down,
+// as observed by the package's default Watcher. The default watcher is
+// started lazily on first use.
+func Modifiers() []Modifier {
+	startDefaultWatcher()
+	return defaultWatcher.Modifiers()
+}
+
+// ModifiersChanged returns a channel that receives an event every time
+// the set of held modifier keys changes, as observed by the package's
+// default Watcher. The default watcher is started lazily on first use.
+func ModifiersChanged() <-chan ModifiersEvent {
+	startDefaultWatcher()
+	return defaultWatcher.ModifiersChanged()
+}
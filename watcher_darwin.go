@@ -0,0 +1,106 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build darwin
+
+package hotkey
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices
+#include <ApplicationServices/ApplicationServices.h>
+
+extern void watcherFlagsChanged(uintptr_t handle, CGEventFlags flags);
+
+static CGEventRef watcherTapCallback(CGEventTapProxy proxy, CGEventType type, CGEventRef event, void *refcon) {
+	if (type == kCGEventFlagsChanged) {
+		watcherFlagsChanged((uintptr_t)refcon, CGEventGetFlags(event));
+	}
+	return event;
+}
+
+static CFMachPortRef watcherCreateTap(uintptr_t handle) {
+	CGEventMask mask = CGEventMaskBit(kCGEventFlagsChanged);
+	return CGEventTapCreate(kCGHIDEventTap, kCGHeadInsertEventTap,
+		kCGEventTapOptionListenOnly, mask, watcherTapCallback, (void *)handle);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+)
+
+// darwinModifierFlags maps this package's modifiers to the CGEventFlags
+// bit CGEventTap reports them with.
+var darwinModifierFlags = map[Modifier]C.CGEventFlags{
+	ModCtrl:   C.kCGEventFlagMaskControl,
+	ModOption: C.kCGEventFlagMaskAlternate,
+	ModShift:  C.kCGEventFlagMaskShift,
+	ModCmd:    C.kCGEventFlagMaskCommand,
+}
+
+type platformWatcher struct {
+	tap    C.CFMachPortRef
+	source C.CFRunLoopSourceRef
+}
+
+var (
+	watcherHandlesMu sync.Mutex
+	watcherHandles   = map[C.uintptr_t]*Watcher{}
+	watcherHandleSeq C.uintptr_t
+)
+
+// start installs a listen-only CGEventTap for kCGEventFlagsChanged.
+// Like Hotkey's own registration on macOS, this must run with an active
+// CFRunLoop on the main thread, e.g. via golang.design/x/hotkey/mainthread.
+func (w *Watcher) start() error {
+	watcherHandlesMu.Lock()
+	watcherHandleSeq++
+	handle := watcherHandleSeq
+	watcherHandles[handle] = w
+	watcherHandlesMu.Unlock()
+
+	tap := C.watcherCreateTap(handle)
+	if tap == 0 {
+		watcherHandlesMu.Lock()
+		delete(watcherHandles, handle)
+		watcherHandlesMu.Unlock()
+		return errors.New("hotkey: failed to create modifier event tap (missing accessibility permission?)")
+	}
+	source := C.CFMachPortCreateRunLoopSource(0, tap, 0)
+	C.CFRunLoopAddSource(C.CFRunLoopGetMain(), source, C.kCFRunLoopCommonModes)
+	C.CGEventTapEnable(tap, C.true)
+
+	w.tap = tap
+	w.source = source
+	return nil
+}
+
+func (w *Watcher) stop() error {
+	if w.tap == 0 {
+		return nil
+	}
+	C.CGEventTapEnable(w.tap, C.false)
+	C.CFRunLoopRemoveSource(C.CFRunLoopGetMain(), w.source, C.kCFRunLoopCommonModes)
+	C.CFRelease(C.CFTypeRef(w.source))
+	C.CFRelease(C.CFTypeRef(w.tap))
+	w.tap = 0
+	return nil
+}
+
+//export watcherFlagsChanged
+func watcherFlagsChanged(handle C.uintptr_t, flags C.CGEventFlags) {
+	watcherHandlesMu.Lock()
+	w := watcherHandles[handle]
+	watcherHandlesMu.Unlock()
+	if w == nil {
+		return
+	}
+	for mod, bit := range darwinModifierFlags {
+		w.setModifier(mod, flags&bit != 0)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build linux
+
+package hotkey
+
+/*
+#cgo LDFLAGS: -lX11
+#include <X11/Xlib.h>
+#include <X11/keysym.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"time"
+)
+
+// watcherPollInterval is how often the modifier keymap is sampled.
+// X11 has no push notification for "a modifier is still held"; XRecord
+// can report individual KeyPress/KeyRelease events, but polling
+// XQueryKeymap is both simpler and, unlike XGrabKey, never prevents
+// other applications from receiving the same modifier keys.
+const watcherPollInterval = 15 * time.Millisecond
+
+// watcherModifierKeysyms lists, for each modifier this package exposes,
+// the X11 keysyms of the physical keys that produce it.
+var watcherModifierKeysyms = map[Modifier][]C.KeySym{
+	ModCtrl:   {C.XK_Control_L, C.XK_Control_R},
+	ModOption: {C.XK_Alt_L, C.XK_Alt_R},
+	ModShift:  {C.XK_Shift_L, C.XK_Shift_R},
+	ModCmd:    {C.XK_Super_L, C.XK_Super_R},
+}
+
+type platformWatcher struct {
+	display *C.Display
+	done    chan struct{}
+}
+
+func (w *Watcher) start() error {
+	display := C.XOpenDisplay(nil)
+	if display == nil {
+		return errors.New("hotkey: cannot open X11 display")
+	}
+	w.display = display
+	w.done = make(chan struct{})
+	go w.poll()
+	return nil
+}
+
+func (w *Watcher) stop() error {
+	if w.display == nil {
+		return nil
+	}
+	close(w.done)
+	C.XCloseDisplay(w.display)
+	w.display = nil
+	return nil
+}
+
+// poll samples the X server's keymap at a fixed interval and reports
+// every tracked modifier's held state; setModifier discards samples
+// that did not actually change anything.
+func (w *Watcher) poll() {
+	ticker := time.NewTicker(watcherPollInterval)
+	defer ticker.Stop()
+
+	var keymap [32]C.char
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		C.XQueryKeymap(w.display, &keymap[0])
+		for mod, keysyms := range watcherModifierKeysyms {
+			w.setModifier(mod, w.keysymsHeld(keymap, keysyms))
+		}
+	}
+}
+
+func (w *Watcher) keysymsHeld(keymap [32]C.char, keysyms []C.KeySym) bool {
+	for _, ks := range keysyms {
+		kc := C.XKeysymToKeycode(w.display, ks)
+		if kc == 0 {
+			continue
+		}
+		if keymap[kc/8]&(1<<(kc%8)) != 0 {
+			return true
+		}
+	}
+	return false
+}
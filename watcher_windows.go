@@ -0,0 +1,135 @@
+// Copyright 2021 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+//
+// Written by Changkun Ou <changkun.de>
+
+//go:build windows
+
+package hotkey
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	whKeyboardLL  = 13
+	vkControl     = 0x11
+	vkMenu        = 0x12 // Alt
+	vkShift       = 0x10
+	vkLWin        = 0x5B
+	watcherPollMS = 15
+)
+
+var (
+	user32                  = syscall.NewLazyDLL("user32.dll")
+	kernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procSetWindowsHookExW   = user32.NewProc("SetWindowsHookExW")
+	procUnhookWindowsHookEx = user32.NewProc("UnhookWindowsHookEx")
+	procCallNextHookEx      = user32.NewProc("CallNextHookEx")
+	procGetAsyncKeyState    = user32.NewProc("GetAsyncKeyState")
+	procGetModuleHandleW    = kernel32.NewProc("GetModuleHandleW")
+)
+
+// windowsModifierVKs maps this package's modifiers to their Windows
+// virtual-key codes.
+var windowsModifierVKs = map[Modifier]uintptr{
+	ModCtrl:   vkControl,
+	ModOption: vkMenu,
+	ModShift:  vkShift,
+	ModCmd:    vkLWin,
+}
+
+type platformWatcher struct {
+	hook uintptr
+	done chan struct{}
+}
+
+var (
+	watcherMu      sync.Mutex
+	activeWatchers = map[*Watcher]bool{}
+)
+
+// start installs a WH_KEYBOARD_LL hook. The hook procedure itself only
+// triggers a GetAsyncKeyState sample of every tracked modifier: unlike
+// a normal keyboard key, Windows does not deliver a distinct low-level
+// event per modifier combination change, so sampling on every keyboard
+// event (and on a timer, to catch releases outside this process's
+// input focus) is what actually observes the modifier state.
+func (w *Watcher) start() error {
+	mod, _, _ := procGetModuleHandleW.Call(0)
+	hook, _, err := procSetWindowsHookExW.Call(
+		uintptr(whKeyboardLL),
+		syscall.NewCallback(watcherHookProc),
+		mod,
+		0,
+	)
+	if hook == 0 {
+		return errors.New("hotkey: SetWindowsHookExW failed: " + err.Error())
+	}
+
+	w.hook = hook
+	w.done = make(chan struct{})
+
+	watcherMu.Lock()
+	activeWatchers[w] = true
+	watcherMu.Unlock()
+
+	go w.poll()
+	return nil
+}
+
+func (w *Watcher) stop() error {
+	if w.hook == 0 {
+		return nil
+	}
+	procUnhookWindowsHookEx.Call(w.hook)
+
+	watcherMu.Lock()
+	delete(activeWatchers, w)
+	watcherMu.Unlock()
+
+	close(w.done)
+	w.hook = 0
+	return nil
+}
+
+func (w *Watcher) poll() {
+	ticker := time.NewTicker(watcherPollMS * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.sample()
+		}
+	}
+}
+
+func (w *Watcher) sample() {
+	for mod, vk := range windowsModifierVKs {
+		state, _, _ := procGetAsyncKeyState.Call(vk)
+		w.setModifier(mod, state&0x8000 != 0)
+	}
+}
+
+// watcherHookProc is the WH_KEYBOARD_LL hook procedure. It never
+// suppresses a key: it only uses the keyboard event as a hint to sample
+// modifier state immediately, instead of waiting for the next poll
+// tick.
+func watcherHookProc(nCode int32, wParam uintptr, lParam uintptr) uintptr {
+	if nCode >= 0 {
+		watcherMu.Lock()
+		for w := range activeWatchers {
+			w.sample()
+		}
+		watcherMu.Unlock()
+	}
+	ret, _, _ := procCallNextHookEx.Call(0, uintptr(nCode), wParam, lParam)
+	return ret
+}